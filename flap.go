@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold  = 1 // consecutive failed probes before declaring DOWN
+	defaultRecoveryThreshold = 1 // consecutive successful probes before declaring UP
+	defaultFlapThreshold     = 5.0
+	defaultFlapDecayHalfLife = 10 * time.Minute
+	defaultAlertBurst        = 1
+)
+
+// failureThreshold, recoveryThreshold, flapThreshold, and quietHoursSpec
+// resolve a per-target override over the global Config default.
+
+func failureThreshold(spec TargetSpec, cfg Config) int {
+	if spec.FailureThreshold > 0 {
+		return spec.FailureThreshold
+	}
+	if cfg.FailureThreshold > 0 {
+		return cfg.FailureThreshold
+	}
+	return defaultFailureThreshold
+}
+
+func recoveryThreshold(spec TargetSpec, cfg Config) int {
+	if spec.RecoveryThreshold > 0 {
+		return spec.RecoveryThreshold
+	}
+	if cfg.RecoveryThreshold > 0 {
+		return cfg.RecoveryThreshold
+	}
+	return defaultRecoveryThreshold
+}
+
+func flapThreshold(spec TargetSpec, cfg Config) float64 {
+	if spec.FlapThreshold > 0 {
+		return spec.FlapThreshold
+	}
+	if cfg.FlapThreshold > 0 {
+		return cfg.FlapThreshold
+	}
+	return defaultFlapThreshold
+}
+
+func flapDecayHalfLife(cfg Config) time.Duration {
+	if cfg.FlapDecayHalfLifeSeconds > 0 {
+		return time.Duration(cfg.FlapDecayHalfLifeSeconds) * time.Second
+	}
+	return defaultFlapDecayHalfLife
+}
+
+func quietHoursSpec(spec TargetSpec, cfg Config) string {
+	if spec.QuietHours != "" {
+		return spec.QuietHours
+	}
+	return cfg.QuietHours
+}
+
+// quietWindow is a daily local-time window such as "22:00-07:00". It may
+// wrap past midnight.
+type quietWindow struct {
+	start time.Duration // time of day, as an offset from midnight
+	end   time.Duration
+}
+
+// parseQuietHours parses a "HH:MM-HH:MM" window. The zero value and ok=false
+// are returned for an empty or malformed spec, which callers treat as "no
+// quiet hours".
+func parseQuietHours(s string) (w quietWindow, ok bool) {
+	parts := strings.SplitN(strings.TrimSpace(s), "-", 2)
+	if len(parts) != 2 {
+		return quietWindow{}, false
+	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return quietWindow{}, false
+	}
+	end, err := parseClock(parts[1])
+	if err != nil {
+		return quietWindow{}, false
+	}
+	return quietWindow{start: start, end: end}, true
+}
+
+func parseClock(s string) (time.Duration, error) {
+	hm := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	if len(hm) != 2 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	h, err := strconv.Atoi(hm[0])
+	if err != nil {
+		return 0, err
+	}
+	m, err := strconv.Atoi(hm[1])
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// contains reports whether t's local clock time falls within the window.
+func (w quietWindow) contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if w.start <= w.end {
+		return offset >= w.start && offset < w.end
+	}
+	// Window wraps past midnight, e.g. 22:00-07:00.
+	return offset >= w.start || offset < w.end
+}
+
+// rateLimiter is a token bucket shared across every outbound alert, so a
+// flapping target can't drown out alerts for everything else. A rate of 0
+// disables limiting entirely.
+type rateLimiter struct {
+	mu         sync.Mutex
+	rate       float64 // tokens added per second
+	burst      float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+func newRateLimiter(perMinute float64, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = defaultAlertBurst
+	}
+	return &rateLimiter{
+		rate:       perMinute / 60,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		lastRefill: time.Now(),
+	}
+}
+
+// Allow reports whether an alert may be sent right now, consuming a token
+// if so.
+func (r *rateLimiter) Allow() bool {
+	if r.rate <= 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens = math.Min(r.burst, r.tokens+now.Sub(r.lastRefill).Seconds()*r.rate)
+	r.lastRefill = now
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// flapTracker maintains an exponentially decaying flap score per target:
+// every state transition adds 1, and the score decays toward 0 with
+// halfLife. Once a target's score reaches its threshold, RecordTransition
+// reports that transition alerts should be muted until the score decays
+// back below it.
+type flapTracker struct {
+	halfLife time.Duration
+
+	mu    sync.Mutex
+	state map[string]*flapState
+}
+
+type flapState struct {
+	score   float64
+	updated time.Time
+	muted   bool
+}
+
+func newFlapTracker(halfLife time.Duration) *flapTracker {
+	return &flapTracker{halfLife: halfLife, state: make(map[string]*flapState)}
+}
+
+func (f *flapTracker) decayLocked(st *flapState, now time.Time) {
+	elapsed := now.Sub(st.updated)
+	if elapsed <= 0 {
+		return
+	}
+	st.score *= math.Pow(0.5, float64(elapsed)/float64(f.halfLife))
+	st.updated = now
+}
+
+// Score reports name's current flap score, decayed to now.
+func (f *flapTracker) Score(name string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st, ok := f.state[name]
+	if !ok {
+		return 0
+	}
+	f.decayLocked(st, time.Now())
+	return st.score
+}
+
+// RecordTransition records an up/down transition for name and reports its
+// decayed score, whether this is the transition that just crossed
+// threshold (so a single "flapping" alert should fire), and whether
+// ordinary transition alerts should stay muted.
+func (f *flapTracker) RecordTransition(name string, threshold float64) (score float64, startFlapping, muted bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	st, ok := f.state[name]
+	if !ok {
+		st = &flapState{updated: now}
+		f.state[name] = st
+	}
+	f.decayLocked(st, now)
+	st.score++
+
+	if st.score >= threshold {
+		startFlapping = !st.muted
+		st.muted = true
+	} else {
+		st.muted = false
+	}
+	return st.score, startFlapping, st.muted
+}
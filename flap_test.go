@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuietHoursWrapsMidnight(t *testing.T) {
+	window, ok := parseQuietHours("22:00-07:00")
+	if !ok {
+		t.Fatal("expected a valid window")
+	}
+
+	ref := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		hour int
+		want bool
+	}{
+		{23, true},
+		{3, true},
+		{7, false},
+		{12, false},
+		{21, false},
+	}
+	for _, c := range cases {
+		got := window.contains(ref.Add(time.Duration(c.hour) * time.Hour))
+		if got != c.want {
+			t.Errorf("contains at hour %d = %v, want %v", c.hour, got, c.want)
+		}
+	}
+}
+
+func TestParseQuietHoursInvalid(t *testing.T) {
+	if _, ok := parseQuietHours(""); ok {
+		t.Error("empty spec should be invalid")
+	}
+	if _, ok := parseQuietHours("not-a-window"); ok {
+		t.Error("garbage spec should be invalid")
+	}
+}
+
+func TestFlapTrackerMutesAboveThreshold(t *testing.T) {
+	f := newFlapTracker(time.Hour) // long half-life so decay doesn't interfere
+
+	// Threshold sits half a transition above an integer score, so the
+	// comparison isn't tripped by the tiny floating-point decay applied on
+	// every call.
+	const threshold = 4.5
+
+	for i := 0; i < 4; i++ {
+		_, startFlapping, muted := f.RecordTransition("local", threshold)
+		if startFlapping || muted {
+			t.Fatalf("transition %d: startFlapping=%v muted=%v, want both false below threshold", i, startFlapping, muted)
+		}
+	}
+
+	score, startFlapping, muted := f.RecordTransition("local", threshold)
+	if !startFlapping || !muted {
+		t.Fatalf("5th transition: startFlapping=%v muted=%v, want both true at threshold", startFlapping, muted)
+	}
+	if score < threshold {
+		t.Errorf("score = %f, want >= %f", score, threshold)
+	}
+
+	// A further transition while still above threshold stays muted but
+	// doesn't re-fire the "started flapping" alert.
+	_, startFlapping, muted = f.RecordTransition("local", threshold)
+	if startFlapping {
+		t.Error("startFlapping should only be true once per flapping episode")
+	}
+	if !muted {
+		t.Error("expected transitions to stay muted while above threshold")
+	}
+}
+
+func TestFlapTrackerScoreDecays(t *testing.T) {
+	f := newFlapTracker(time.Minute)
+	f.RecordTransition("local", 100) // score = 1, well below any realistic threshold
+
+	st := f.state["local"]
+	st.updated = st.updated.Add(-time.Minute) // pretend a full half-life has elapsed
+
+	score := f.Score("local")
+	if score < 0.4 || score > 0.6 {
+		t.Errorf("score after one half-life = %f, want ~0.5", score)
+	}
+}
+
+func TestRateLimiterAllowsBurstThenThrottles(t *testing.T) {
+	r := newRateLimiter(60, 2) // 1/sec refill, burst of 2
+
+	if !r.Allow() || !r.Allow() {
+		t.Fatal("expected the initial burst to be allowed")
+	}
+	if r.Allow() {
+		t.Error("expected the bucket to be empty after consuming the burst")
+	}
+}
+
+func TestRateLimiterUnlimitedWhenRateIsZero(t *testing.T) {
+	r := newRateLimiter(0, 1)
+	for i := 0; i < 10; i++ {
+		if !r.Allow() {
+			t.Fatalf("call %d: expected unlimited rate to always allow", i)
+		}
+	}
+}
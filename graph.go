@@ -0,0 +1,59 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"time"
+)
+
+const (
+	sparklineWidth  = 240
+	sparklineHeight = 60
+)
+
+// renderSparkline draws a simple bar-per-check sparkline: green bars scaled
+// to latency for up checks, a full-height red bar for down checks.
+func renderSparkline(results []CheckResult) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, sparklineWidth, sparklineHeight))
+
+	bg := color.RGBA{R: 24, G: 24, B: 27, A: 255}
+	for y := 0; y < sparklineHeight; y++ {
+		for x := 0; x < sparklineWidth; x++ {
+			img.Set(x, y, bg)
+		}
+	}
+
+	if len(results) == 0 {
+		return img
+	}
+
+	var maxLatency time.Duration
+	for _, r := range results {
+		if r.Latency > maxLatency {
+			maxLatency = r.Latency
+		}
+	}
+	if maxLatency == 0 {
+		maxLatency = time.Millisecond
+	}
+
+	up := color.RGBA{R: 34, G: 197, B: 94, A: 255}
+	down := color.RGBA{R: 239, G: 68, B: 68, A: 255}
+
+	for i, r := range results {
+		x := i * sparklineWidth / len(results)
+
+		barHeight := int(float64(r.Latency) / float64(maxLatency) * float64(sparklineHeight-1))
+		col := up
+		if !r.Up {
+			col = down
+			barHeight = sparklineHeight - 1
+		}
+
+		for y := sparklineHeight - 1; y >= sparklineHeight-1-barHeight; y-- {
+			img.Set(x, y, col)
+		}
+	}
+
+	return img
+}
@@ -1,31 +1,129 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"image/png"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
-	"os/exec"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
+// TargetSpec describes one thing to monitor.
+type TargetSpec struct {
+	Name           string `json:"name"`
+	Kind           string `json:"kind"`            // icmp, tcp, http, dns
+	Address        string `json:"address"`         // host, host:port, or URL depending on Kind
+	Interval       int    `json:"interval"`        // seconds; falls back to Config.PingInterval
+	Timeout        int    `json:"timeout"`         // seconds; falls back to defaultProbeTimeout
+	ExpectedStatus int    `json:"expected_status"` // http only; 0 means "any non-error status"
+	ExpectedBody   string `json:"expected_body"`   // http only; substring the response body must contain, empty means "don't check"
+
+	// Hysteresis, flap detection, and quiet hours. Each overrides the
+	// matching Config default for this target only; 0/empty means "use the
+	// default". See failureThreshold, recoveryThreshold, flapThreshold, and
+	// quietHoursSpec in flap.go.
+	FailureThreshold  int     `json:"failure_threshold"`
+	RecoveryThreshold int     `json:"recovery_threshold"`
+	FlapThreshold     float64 `json:"flap_threshold"`
+	QuietHours        string  `json:"quiet_hours"` // "HH:MM-HH:MM", e.g. "22:00-07:00"
+}
+
 type Config struct {
-	Target       string `json:"target"`        // IP or hostname to ping
-	BotToken     string `json:"bot_token"`     // Telegram bot token
-	ChatID       string `json:"chat_id"`       // Telegram chat ID
-	PingInterval int    `json:"ping_interval"` // Interval in seconds (default 300 = 5min)
+	Targets      []TargetSpec     `json:"targets"`
+	Notifiers    []NotifierConfig `json:"notifiers"`
+	PingInterval int              `json:"ping_interval"` // default interval in seconds (default 300 = 5min)
+
+	// HistoryDBPath, if set, enables the BoltDB-backed history store: every
+	// check and state transition is recorded there, powering /metrics,
+	// /history, /graph, and the uptime percentages in /status.
+	HistoryDBPath        string `json:"history_db_path"`
+	HistoryRetentionDays int    `json:"history_retention_days"` // default 30; 0 keeps history forever
+
+	// MetricsAddr, if set, serves /metrics, /history, and /graph on this
+	// address (e.g. ":9090").
+	MetricsAddr string `json:"metrics_addr"`
+
+	// Global defaults for hysteresis, flap detection, and alert-storm
+	// suppression; see TargetSpec for per-target overrides.
+	FailureThreshold         int     `json:"failure_threshold"`            // default 1 (alert on first failed probe)
+	RecoveryThreshold        int     `json:"recovery_threshold"`           // default 1
+	FlapThreshold            float64 `json:"flap_threshold"`               // default 5
+	FlapDecayHalfLifeSeconds int     `json:"flap_decay_half_life_seconds"` // default 600 (10m)
+	AlertRateLimit           float64 `json:"alert_rate_limit"`             // max alerts/minute across all targets; 0 = unlimited
+	AlertBurst               int     `json:"alert_burst"`                  // token-bucket burst size; default 1
+	QuietHours               string  `json:"quiet_hours"`                  // default "HH:MM-HH:MM" window, e.g. "22:00-07:00"
+
+	// Legacy single-target, Telegram-only fields. Still accepted so
+	// existing config.json files keep working; loadConfig folds them into
+	// Targets / Notifiers.
+	Target   string `json:"target"`
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"`
 }
 
-type Monitor struct {
-	config     Config
+const (
+	defaultProbeTimeout = 10 * time.Second
+
+	pingQueueSize      = 8
+	pingWorkerCount    = 2
+	pingDebounceWindow = 2 * time.Second
+)
+
+// targetState is the per-target mutable state that used to live directly on
+// Monitor back when there was only ever one target.
+type targetState struct {
+	spec       TargetSpec
 	isUp       bool
 	lastChange time.Time
 	lastCheck  time.Time
-	mu         sync.RWMutex
+
+	// consecutiveFails and consecutiveOK count consecutive probe results
+	// since the last flip, used to apply failureThreshold/recoveryThreshold
+	// hysteresis before isUp is updated.
+	consecutiveFails int
+	consecutiveOK    int
+}
+
+// pingRequest is an on-demand check triggered by a /ping command. An empty
+// name means "check every target".
+type pingRequest struct {
+	name  string
+	reply ReplyFunc
+}
+
+type Monitor struct {
+	config    Config
+	ctx       context.Context
+	notifiers []Notifier
+	store     *Store // nil unless Config.HistoryDBPath is set
+
+	flap         *flapTracker
+	alertLimiter *rateLimiter
+
+	mu      sync.RWMutex
+	targets map[string]*targetState
+
+	pingCh      chan pingRequest
+	pingMu      sync.Mutex
+	pingPending map[string]time.Time
+	wg          sync.WaitGroup
+
+	// quietMu guards quietPending/quietActive, which batch alerts raised
+	// while a target is inside its quiet_hours window into a single digest
+	// sent once the window ends.
+	quietMu      sync.Mutex
+	quietPending map[string][]Message
+	quietActive  map[string]bool
 }
 
 func loadConfig(path string) (Config, error) {
@@ -41,178 +139,503 @@ func loadConfig(path string) (Config, error) {
 	if cfg.PingInterval == 0 {
 		cfg.PingInterval = 300 // default 5 minutes
 	}
+	if len(cfg.Targets) == 0 && cfg.Target != "" {
+		kind := "icmp"
+		if strings.HasPrefix(cfg.Target, "http://") || strings.HasPrefix(cfg.Target, "https://") {
+			kind = "http"
+		}
+		cfg.Targets = []TargetSpec{{
+			Name:    cfg.Target,
+			Kind:    kind,
+			Address: cfg.Target,
+		}}
+	}
+	if len(cfg.Notifiers) == 0 && cfg.BotToken != "" {
+		cfg.Notifiers = []NotifierConfig{{
+			Type:     "telegram",
+			BotToken: cfg.BotToken,
+			ChatID:   cfg.ChatID,
+		}}
+	}
+	if cfg.HistoryDBPath != "" && cfg.HistoryRetentionDays == 0 {
+		cfg.HistoryRetentionDays = 30
+	}
 	return cfg, nil
 }
 
-func (m *Monitor) ping() bool {
-	// Check if target is a URL (HTTP check) or host (ICMP ping)
-	if strings.HasPrefix(m.config.Target, "http://") || strings.HasPrefix(m.config.Target, "https://") {
-		return m.httpCheck()
+// NewMonitor builds a Monitor bound to ctx: every background goroutine it
+// starts (the ping worker pool, and runCommanders/runTicker once the caller
+// starts them) exits when ctx is canceled.
+func NewMonitor(ctx context.Context, config Config) *Monitor {
+	targets := make(map[string]*targetState, len(config.Targets))
+	for _, spec := range config.Targets {
+		targets[spec.Name] = &targetState{spec: spec}
+	}
+
+	m := &Monitor{
+		config:       config,
+		ctx:          ctx,
+		notifiers:    buildNotifiers(config),
+		targets:      targets,
+		flap:         newFlapTracker(flapDecayHalfLife(config)),
+		alertLimiter: newRateLimiter(config.AlertRateLimit, config.AlertBurst),
+		pingCh:       make(chan pingRequest, pingQueueSize),
+		pingPending:  make(map[string]time.Time),
+		quietPending: make(map[string][]Message),
+		quietActive:  make(map[string]bool),
 	}
-	// Use ping command with 3 attempts, 2 second timeout each
-	cmd := exec.Command("ping", "-c", "3", "-W", "2", m.config.Target)
-	err := cmd.Run()
-	return err == nil
+
+	for i := 0; i < pingWorkerCount; i++ {
+		m.wg.Add(1)
+		go m.pingWorker()
+	}
+
+	if config.HistoryDBPath != "" {
+		store, err := OpenStore(config.HistoryDBPath, config.HistoryRetentionDays)
+		if err != nil {
+			log.Printf("store: disabled, failed to open %s: %v", config.HistoryDBPath, err)
+		} else {
+			m.store = store
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				store.RunCompactor(ctx, defaultCompactInterval)
+			}()
+		}
+	}
+
+	return m
 }
 
-func (m *Monitor) httpCheck() bool {
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(m.config.Target)
-	if err != nil {
-		return false
+// pingWorker drains on-demand /ping requests. Running a small fixed pool of
+// these (instead of one goroutine per /ping command) bounds how much check()
+// work a burst of commands can trigger concurrently.
+func (m *Monitor) pingWorker() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case req := <-m.pingCh:
+			if req.name != "" {
+				m.check(req.name)
+				req.reply(m.getStatus(req.name))
+			} else {
+				m.checkAll()
+				req.reply(m.statusAll())
+			}
+		}
 	}
-	defer resp.Body.Close()
-	// Any response (even 401, 403) means the server is reachable
-	return resp.StatusCode > 0
 }
 
-func (m *Monitor) sendTelegram(message string) error {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", m.config.BotToken)
-	resp, err := http.PostForm(apiURL, url.Values{
-		"chat_id": {m.config.ChatID},
-		"text":    {message},
-	})
-	if err != nil {
-		return err
+// requestPing enqueues an on-demand check. Duplicate requests for the same
+// target within pingDebounceWindow are dropped rather than queued, and the
+// queue itself is bounded so a flood of /ping commands can't pile up
+// unbounded work.
+func (m *Monitor) requestPing(name string, reply ReplyFunc) {
+	m.pingMu.Lock()
+	now := time.Now()
+	if last, ok := m.pingPending[name]; ok && now.Sub(last) < pingDebounceWindow {
+		m.pingMu.Unlock()
+		log.Printf("Dropping duplicate /ping for %q", name)
+		return
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("telegram API returned status %d", resp.StatusCode)
+	m.pingPending[name] = now
+	m.pingMu.Unlock()
+
+	select {
+	case m.pingCh <- pingRequest{name: name, reply: reply}:
+	default:
+		log.Printf("Ping queue full, dropping request for %q", name)
 	}
-	return nil
 }
 
-func (m *Monitor) check() {
-	isUp := m.ping()
+// check runs the probe for a single target by name, applies
+// failure/recovery hysteresis, and records any resulting state transition.
+func (m *Monitor) check(name string) {
+	m.mu.RLock()
+	ts, ok := m.targets[name]
+	m.mu.RUnlock()
+	if !ok {
+		log.Printf("check: unknown target %q", name)
+		return
+	}
+
+	m.maybeFlushQuietDigest(ts.spec)
+
+	prober, ok := proberRegistry[ts.spec.Kind]
+	if !ok {
+		log.Printf("%s: unknown probe kind %q", name, ts.spec.Kind)
+		return
+	}
+
+	timeout := defaultProbeTimeout
+	if ts.spec.Timeout > 0 {
+		timeout = time.Duration(ts.spec.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	isUp := prober.Probe(ctx, ts.spec) == nil
+	latency := time.Since(start)
 	now := time.Now()
 
+	if m.store != nil {
+		if err := m.store.RecordCheck(CheckResult{Target: ts.spec.Name, Timestamp: now, Up: isUp, Latency: latency}); err != nil {
+			log.Printf("store: record check failed: %v", err)
+		}
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.lastCheck = now
-	prevUp := m.isUp
-	firstCheck := m.lastChange.IsZero()
+	ts.lastCheck = now
+	firstCheck := ts.lastChange.IsZero()
+	prevUp := ts.isUp
 
-	if isUp != prevUp || firstCheck {
-		m.isUp = isUp
-		m.lastChange = now
+	if isUp {
+		ts.consecutiveFails = 0
+		ts.consecutiveOK++
+	} else {
+		ts.consecutiveOK = 0
+		ts.consecutiveFails++
+	}
 
-		var msg string
-		if isUp {
-			if firstCheck {
-				msg = fmt.Sprintf("🟢 Network monitor started. %s is UP.", m.config.Target)
-			} else {
-				downtime := now.Sub(m.lastChange).Round(time.Second)
-				msg = fmt.Sprintf("🟢 %s is back UP! (was down for %s)", m.config.Target, downtime)
-			}
-		} else {
-			if firstCheck {
-				msg = fmt.Sprintf("🔴 Network monitor started. %s is DOWN!", m.config.Target)
-			} else {
-				msg = fmt.Sprintf("🔴 %s is DOWN!", m.config.Target)
+	declared := prevUp
+	switch {
+	case firstCheck:
+		declared = isUp
+	case isUp && !prevUp && ts.consecutiveOK >= recoveryThreshold(ts.spec, m.config):
+		declared = true
+	case !isUp && prevUp && ts.consecutiveFails >= failureThreshold(ts.spec, m.config):
+		declared = false
+	}
+
+	if declared != prevUp || firstCheck {
+		downSince := ts.lastChange
+		ts.isUp = declared
+		ts.lastChange = now
+
+		if m.store != nil {
+			if err := m.store.RecordTransition(StateTransition{Target: ts.spec.Name, Timestamp: now, Up: declared}); err != nil {
+				log.Printf("store: record transition failed: %v", err)
 			}
 		}
 
-		log.Printf("Status change: %s", msg)
-		if err := m.sendTelegram(msg); err != nil {
-			log.Printf("Failed to send Telegram message: %v", err)
+		msg := Message{Target: ts.spec.Name, Severity: SeverityOK}
+		if !declared {
+			msg.Severity = SeverityCritical
+		}
+
+		switch {
+		case firstCheck:
+			msg.Text = fmt.Sprintf("Network monitor started. %s is %s.", ts.spec.Name, upDownWord(declared))
+		case declared:
+			msg.Downtime = now.Sub(downSince).Round(time.Second)
+			msg.Text = fmt.Sprintf("%s is back UP! (was down for %s)", ts.spec.Name, msg.Downtime)
+		default:
+			msg.Text = fmt.Sprintf("%s is DOWN!", ts.spec.Name)
+		}
+
+		log.Printf("Status change: %s", msg.Text)
+
+		suppressed := false
+		if !firstCheck {
+			score, startFlapping, muted := m.flap.RecordTransition(ts.spec.Name, flapThreshold(ts.spec, m.config))
+			if startFlapping {
+				m.sendAlert(ts.spec, Message{
+					Target:   ts.spec.Name,
+					Severity: SeverityWarning,
+					Text:     fmt.Sprintf("%s is flapping (flap score %.1f) — muting further up/down alerts until it settles.", ts.spec.Name, score),
+				})
+			}
+			suppressed = muted
+		}
+		if !suppressed {
+			m.sendAlert(ts.spec, msg)
 		}
 	} else {
-		log.Printf("Ping %s: still %s", m.config.Target, map[bool]string{true: "UP", false: "DOWN"}[isUp])
+		log.Printf("Check %s: still %s", ts.spec.Name, upDownWord(declared))
+	}
+}
+
+// sendAlert applies the global alert rate limit, then routes msg to notify
+// or to spec's quiet-hours digest.
+func (m *Monitor) sendAlert(spec TargetSpec, msg Message) {
+	if !m.alertLimiter.Allow() {
+		log.Printf("%s: alert rate limit exceeded, dropping: %s", spec.Name, msg.Text)
+		return
 	}
+	m.deliverOrQueue(spec, msg)
 }
 
-func (m *Monitor) getStatus() string {
+// inQuietHours reports whether spec's quiet_hours window currently covers
+// the local time.
+func (m *Monitor) inQuietHours(spec TargetSpec) bool {
+	window, ok := parseQuietHours(quietHoursSpec(spec, m.config))
+	return ok && window.contains(time.Now())
+}
+
+// deliverOrQueue sends msg immediately, unless spec is currently inside its
+// quiet_hours window, in which case msg is batched into that target's
+// digest instead.
+func (m *Monitor) deliverOrQueue(spec TargetSpec, msg Message) {
+	if m.inQuietHours(spec) {
+		m.quietMu.Lock()
+		m.quietActive[spec.Name] = true
+		m.quietPending[spec.Name] = append(m.quietPending[spec.Name], msg)
+		m.quietMu.Unlock()
+		return
+	}
+	m.notify(msg)
+}
+
+// maybeFlushQuietDigest sends spec's pending quiet-hours digest, if any,
+// once its window has ended. Called on every check so a digest goes out
+// promptly even if the target doesn't transition again right away.
+func (m *Monitor) maybeFlushQuietDigest(spec TargetSpec) {
+	if m.inQuietHours(spec) {
+		return
+	}
+
+	m.quietMu.Lock()
+	wasActive := m.quietActive[spec.Name]
+	pending := m.quietPending[spec.Name]
+	delete(m.quietPending, spec.Name)
+	m.quietActive[spec.Name] = false
+	m.quietMu.Unlock()
+
+	if !wasActive || len(pending) == 0 {
+		return
+	}
+
+	lines := make([]string, len(pending))
+	for i, msg := range pending {
+		lines[i] = "- " + msg.Text
+	}
+	m.notify(Message{
+		Target:   spec.Name,
+		Severity: SeverityInfo,
+		Text:     fmt.Sprintf("Quiet hours digest for %s (%d event(s)):\n%s", spec.Name, len(pending), strings.Join(lines, "\n")),
+	})
+}
+
+func upDownWord(isUp bool) string {
+	if isUp {
+		return "UP"
+	}
+	return "DOWN"
+}
+
+// checkAll runs check for every configured target.
+func (m *Monitor) checkAll() {
+	for _, name := range m.targetNames() {
+		m.check(name)
+	}
+}
+
+// notify fans a Message out to every configured notifier.
+func (m *Monitor) notify(msg Message) {
+	for _, n := range m.notifiers {
+		if err := n.Send(m.ctx, msg); err != nil {
+			log.Printf("%s: failed to send alert: %v", n.Name(), err)
+		}
+	}
+}
+
+func (m *Monitor) getStatus(name string) string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	ts, ok := m.targets[name]
+	if !ok {
+		return fmt.Sprintf("Unknown target: %s", name)
+	}
+
 	status := "UP 🟢"
-	if !m.isUp {
+	if !ts.isUp {
 		status = "DOWN 🔴"
 	}
 
-	duration := time.Since(m.lastChange).Round(time.Second)
-	lastCheckAgo := time.Since(m.lastCheck).Round(time.Second)
+	duration := time.Since(ts.lastChange).Round(time.Second)
+	lastCheckAgo := time.Since(ts.lastCheck).Round(time.Second)
 
-	return fmt.Sprintf("Target: %s\nStatus: %s\nSince: %s ago\nLast check: %s ago",
-		m.config.Target, status, duration, lastCheckAgo)
-}
+	base := fmt.Sprintf("Target: %s (%s)\nAddress: %s\nStatus: %s\nSince: %s ago\nLast check: %s ago\nConsecutive failures: %d\nFlap score: %.1f",
+		name, ts.spec.Kind, ts.spec.Address, status, duration, lastCheckAgo, ts.consecutiveFails, m.flap.Score(name))
 
-func (m *Monitor) runBot() {
-	offset := 0
-	for {
-		updates, err := m.getUpdates(offset)
+	if m.store == nil {
+		return base
+	}
+
+	var uptimeLines []string
+	for _, win := range uptimeWindows[:2] { // 24h, 7d
+		ratio, mean, err := m.store.UptimeRatio(name, win.Duration)
 		if err != nil {
-			log.Printf("Error getting updates: %v", err)
-			time.Sleep(5 * time.Second)
 			continue
 		}
+		uptimeLines = append(uptimeLines, fmt.Sprintf("%s uptime: %.2f%% (mean latency %s)", win.Label, ratio*100, mean.Round(time.Millisecond)))
+	}
+	if len(uptimeLines) == 0 {
+		return base
+	}
+	return base + "\n" + strings.Join(uptimeLines, "\n")
+}
 
-		for _, update := range updates {
-			offset = update.UpdateID + 1
-			if update.Message == nil {
-				continue
-			}
+// statusAll renders getStatus for every target, sorted by name.
+func (m *Monitor) statusAll() string {
+	names := m.targetNames()
+	if len(names) == 0 {
+		return "No targets configured."
+	}
 
-			text := strings.TrimSpace(update.Message.Text)
-			chatID := fmt.Sprintf("%d", update.Message.Chat.ID)
-
-			log.Printf("Received message from chat %s: %s", chatID, text)
-
-			if strings.HasPrefix(text, "/status") {
-				m.replyToChat(chatID, m.getStatus())
-			} else if strings.HasPrefix(text, "/start") {
-				m.replyToChat(chatID, fmt.Sprintf("Network Monitor Bot\n\nYour chat ID: %s\n\nCommands:\n/status - Check current status", chatID))
-			} else if strings.HasPrefix(text, "/ping") {
-				m.replyToChat(chatID, "Checking now...")
-				go func() {
-					m.check()
-					m.replyToChat(chatID, m.getStatus())
-				}()
-			}
+	statuses := make([]string, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, m.getStatus(name))
+	}
+	return strings.Join(statuses, "\n\n")
+}
+
+// list renders a one-line-per-target summary for the /list command.
+func (m *Monitor) list() string {
+	names := m.targetNames()
+	if len(names) == 0 {
+		return "No targets configured."
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var b strings.Builder
+	b.WriteString("Targets:\n")
+	for _, name := range names {
+		ts := m.targets[name]
+		status := "🟢"
+		if !ts.isUp {
+			status = "🔴"
 		}
+		fmt.Fprintf(&b, "%s %s (%s)\n", status, name, ts.spec.Kind)
+	}
+	return b.String()
+}
 
-		time.Sleep(1 * time.Second)
+func (m *Monitor) targetNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.targets))
+	for name := range m.targets {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
 
-type TelegramUpdate struct {
-	UpdateID int `json:"update_id"`
-	Message  *struct {
-		Text string `json:"text"`
-		Chat struct {
-			ID int64 `json:"id"`
-		} `json:"chat"`
-	} `json:"message"`
+// handleCommand is the CommandHandler shared by every Commander notifier.
+func (m *Monitor) handleCommand(cmd string, args []string, from string, r Responder) {
+	switch cmd {
+	case "/status":
+		if len(args) > 0 {
+			r.Reply(m.getStatus(args[0]))
+		} else {
+			r.Reply(m.statusAll())
+		}
+	case "/list":
+		r.Reply(m.list())
+	case "/start":
+		r.Reply(fmt.Sprintf("Network Monitor Bot\n\nYour ID: %s\n\nCommands:\n/status [name] - Check current status\n/list - List monitored targets\n/graph <name> [hours] - Latency sparkline", from))
+	case "/ping":
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		r.Reply("Checking now...")
+		m.requestPing(name, r.Reply)
+	case "/graph":
+		m.handleGraphCommand(args, r)
+	}
 }
 
-func (m *Monitor) getUpdates(offset int) ([]TelegramUpdate, error) {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates?offset=%d&timeout=30", m.config.BotToken, offset)
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return nil, err
+// handleGraphCommand renders a latency sparkline for the requested target
+// over the requested window (default 24h) and sends it back as a photo.
+func (m *Monitor) handleGraphCommand(args []string, r Responder) {
+	if m.store == nil {
+		r.Reply("History store not configured.")
+		return
+	}
+	if len(args) == 0 {
+		r.Reply("Usage: /graph <name> [hours]")
+		return
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		OK     bool             `json:"ok"`
-		Result []TelegramUpdate `json:"result"`
+	name := args[0]
+	hours := 24
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[1]); err == nil && n > 0 {
+			hours = n
+		}
 	}
-	err = json.NewDecoder(resp.Body).Decode(&result)
+
+	results, err := m.store.History(name, time.Now().Add(-time.Duration(hours)*time.Hour))
 	if err != nil {
-		return nil, err
+		r.Reply(fmt.Sprintf("Could not load history: %v", err))
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, renderSparkline(results)); err != nil {
+		r.Reply(fmt.Sprintf("Could not render graph: %v", err))
+		return
 	}
-	return result.Result, nil
+
+	r.ReplyPhoto(fmt.Sprintf("%s — last %dh", name, hours), buf.Bytes())
 }
 
-func (m *Monitor) replyToChat(chatID, message string) {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", m.config.BotToken)
-	_, err := http.PostForm(apiURL, url.Values{
-		"chat_id": {chatID},
-		"text":    {message},
-	})
-	if err != nil {
-		log.Printf("Failed to reply: %v", err)
+// runCommanders starts Listen on every notifier that supports two-way
+// commands, until m.ctx is canceled.
+func (m *Monitor) runCommanders() {
+	for _, n := range m.notifiers {
+		c, ok := asCommander(n)
+		if !ok {
+			continue
+		}
+		m.wg.Add(1)
+		go func(c Commander) {
+			defer m.wg.Done()
+			c.Listen(m.ctx, m.handleCommand)
+		}(c)
+	}
+}
+
+// runTicker drives periodic checks for a single target at its own interval,
+// until m.ctx is canceled.
+func (m *Monitor) runTicker(spec TargetSpec) {
+	interval := time.Duration(spec.Interval) * time.Second
+	if interval == 0 {
+		interval = time.Duration(m.config.PingInterval) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(spec.Name)
+		}
+	}
+}
+
+// Close waits for every goroutine started on behalf of this Monitor to
+// finish and closes its history store, if any. Callers should cancel the
+// Monitor's context before calling Close.
+func (m *Monitor) Close() {
+	m.wg.Wait()
+	if m.store != nil {
+		if err := m.store.Close(); err != nil {
+			log.Printf("store: close failed: %v", err)
+		}
 	}
 }
 
@@ -227,19 +650,49 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	log.Printf("Starting network monitor for %s (interval: %ds)", config.Target, config.PingInterval)
+	log.Printf("Starting network monitor for %d target(s), %d notifier(s)", len(config.Targets), len(config.Notifiers))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	monitor := NewMonitor(ctx, config)
 
-	monitor := &Monitor{config: config}
+	// Initial check of every target.
+	monitor.checkAll()
 
-	// Initial check
-	monitor.check()
+	// Start the command listeners for every Commander notifier.
+	monitor.runCommanders()
 
-	// Start the Telegram bot listener
-	go monitor.runBot()
+	// One ticker per target so each can run on its own interval.
+	for _, spec := range config.Targets {
+		spec := spec
+		monitor.wg.Add(1)
+		go func() {
+			defer monitor.wg.Done()
+			monitor.runTicker(spec)
+		}()
+	}
+
+	var metricsServer *http.Server
+	if config.MetricsAddr != "" {
+		ms := NewMetricsServer(monitor, monitor.store)
+		metricsServer = &http.Server{Addr: config.MetricsAddr, Handler: ms.Handler()}
+		monitor.wg.Add(1)
+		go func() {
+			defer monitor.wg.Done()
+			log.Printf("Serving metrics on %s", config.MetricsAddr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("metrics server error: %v", err)
+			}
+		}()
+	}
 
-	// Periodic pings
-	ticker := time.NewTicker(time.Duration(config.PingInterval) * time.Second)
-	for range ticker.C {
-		monitor.check()
+	<-ctx.Done()
+	log.Printf("Shutting down...")
+	if metricsServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		metricsServer.Shutdown(shutdownCtx)
+		cancel()
 	}
+	monitor.Close()
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MatrixNotifier sends alerts to a Matrix room via the client-server API. It
+// logs in lazily on the first Send and caches the resulting access token.
+type MatrixNotifier struct {
+	homeserverURL string
+	userID        string
+	password      string
+	roomID        string
+	client        *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+}
+
+func NewMatrixNotifier(cfg NotifierConfig) *MatrixNotifier {
+	return &MatrixNotifier{
+		homeserverURL: strings.TrimRight(cfg.HomeserverURL, "/"),
+		userID:        cfg.UserID,
+		password:      cfg.Password,
+		roomID:        cfg.RoomID,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (m *MatrixNotifier) Name() string { return "matrix" }
+
+func (m *MatrixNotifier) Send(ctx context.Context, msg Message) error {
+	token, err := m.ensureLoggedIn(ctx)
+	if err != nil {
+		return fmt.Errorf("matrix: login: %w", err)
+	}
+
+	text := fmt.Sprintf("%s %s: %s", severityEmoji(msg.Severity), msg.Target, msg.Text)
+	if msg.Downtime > 0 {
+		text += fmt.Sprintf(" (downtime: %s)", msg.Downtime)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"msgtype": "m.text",
+		"body":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	sendURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message?access_token=%s",
+		m.homeserverURL, url.PathEscape(m.roomID), url.QueryEscape(token))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sendURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("matrix API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (m *MatrixNotifier) ensureLoggedIn(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.accessToken != "" {
+		return m.accessToken, nil
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"type":       "m.login.password",
+		"identifier": map[string]string{"type": "m.id.user", "user": m.userID},
+		"password":   m.password,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.homeserverURL+"/_matrix/client/v3/login", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("login returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	m.accessToken = result.AccessToken
+	return m.accessToken, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMatrixNotifierSendLogsInAndCachesToken(t *testing.T) {
+	var logins int32
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/login"):
+			atomic.AddInt32(&logins, 1)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "tok-123"})
+		case strings.Contains(r.URL.Path, "/send/m.room.message"):
+			if got := r.URL.Query().Get("access_token"); got != "tok-123" {
+				t.Errorf("send request used access_token %q, want tok-123", got)
+			}
+			var body struct {
+				Body string `json:"body"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotBody = body.Body
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"event_id": "$1"})
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	mn := NewMatrixNotifier(NotifierConfig{HomeserverURL: server.URL, UserID: "@bot:example.org", Password: "pw", RoomID: "!room:example.org"})
+
+	if err := mn.Send(context.Background(), Message{Target: "local", Severity: SeverityCritical, Text: "local is DOWN!"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(gotBody, "local") {
+		t.Errorf("expected rendered body to include target name, got %q", gotBody)
+	}
+
+	if err := mn.Send(context.Background(), Message{Target: "local", Severity: SeverityOK, Text: "local is back UP!"}); err != nil {
+		t.Fatalf("second Send: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Errorf("expected the access token to be cached after the first login, got %d logins", got)
+	}
+}
+
+func TestMatrixNotifierSendLoginFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	mn := NewMatrixNotifier(NotifierConfig{HomeserverURL: server.URL, UserID: "@bot:example.org", Password: "wrong", RoomID: "!room:example.org"})
+
+	if err := mn.Send(context.Background(), Message{Target: "local", Text: "hi"}); err == nil {
+		t.Error("expected a failed login to surface as an error from Send")
+	}
+}
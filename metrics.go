@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricsServer exposes Prometheus metrics and JSON/PNG history endpoints
+// backed by a Monitor and its optional Store.
+type MetricsServer struct {
+	monitor *Monitor
+	store   *Store
+}
+
+func NewMetricsServer(monitor *Monitor, store *Store) *MetricsServer {
+	return &MetricsServer{monitor: monitor, store: store}
+}
+
+func (s *MetricsServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/history", s.handleHistory)
+	mux.HandleFunc("/graph", s.handleGraph)
+	return mux
+}
+
+func (s *MetricsServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	var b strings.Builder
+	b.WriteString("# HELP probe_up 1 if the last check for a target succeeded, 0 otherwise\n")
+	b.WriteString("# TYPE probe_up gauge\n")
+	for _, name := range s.monitor.targetNames() {
+		s.monitor.mu.RLock()
+		ts := s.monitor.targets[name]
+		up, lastCheck := ts.isUp, ts.lastCheck
+		s.monitor.mu.RUnlock()
+
+		fmt.Fprintf(&b, "probe_up{target=%q} %d\n", name, boolToInt(up))
+		if !lastCheck.IsZero() {
+			fmt.Fprintf(&b, "probe_duration_seconds{target=%q} %f\n", name, time.Since(lastCheck).Seconds())
+		}
+	}
+
+	if s.store == nil {
+		w.Write([]byte(b.String()))
+		return
+	}
+
+	b.WriteString("# HELP probe_status_transitions_total total number of up/down transitions recorded for a target\n")
+	b.WriteString("# TYPE probe_status_transitions_total counter\n")
+	for _, name := range s.monitor.targetNames() {
+		count, err := s.store.TransitionCount(name)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, "probe_status_transitions_total{target=%q} %d\n", name, count)
+	}
+
+	b.WriteString("# HELP probe_uptime_ratio fraction of checks that were up within the given window\n")
+	b.WriteString("# TYPE probe_uptime_ratio gauge\n")
+	for _, name := range s.monitor.targetNames() {
+		for _, win := range uptimeWindows {
+			ratio, _, err := s.store.UptimeRatio(name, win.Duration)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "probe_uptime_ratio{target=%q,window=%q} %f\n", name, win.Label, ratio)
+		}
+	}
+
+	w.Write([]byte(b.String()))
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *MetricsServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		http.Error(w, "history store not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target is required", http.StatusBadRequest)
+		return
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			http.Error(w, "since must be RFC3339", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	results, err := s.store.History(target, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (s *MetricsServer) handleGraph(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		http.Error(w, "history store not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target is required", http.StatusBadRequest)
+		return
+	}
+
+	hours := 24
+	if raw := r.URL.Query().Get("hours"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			hours = n
+		}
+	}
+
+	results, err := s.store.History(target, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	if err := png.Encode(w, renderSparkline(results)); err != nil {
+		log.Printf("graph: failed to encode PNG: %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeNotifier is an in-memory Notifier/Commander test double: Send just
+// records messages, and Listen blocks until ctx is canceled without ever
+// producing a command.
+type fakeNotifier struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Send(ctx context.Context, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, msg)
+	return nil
+}
+
+func (f *fakeNotifier) Listen(ctx context.Context, handler CommandHandler) {
+	<-ctx.Done()
+}
+
+func TestMonitorStopsCleanlyOnCancel(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	config := Config{
+		Targets: []TargetSpec{{Name: "local", Kind: "tcp", Address: "127.0.0.1:1", Interval: 1}},
+	}
+
+	m := NewMonitor(ctx, config)
+	m.notifiers = []Notifier{&fakeNotifier{}}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.runTicker(config.Targets[0])
+	}()
+	m.runCommanders()
+
+	time.Sleep(60 * time.Millisecond)
+
+	cancel()
+	m.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > baseline {
+		t.Errorf("goroutines leaked after shutdown: baseline=%d after=%d", baseline, after)
+	}
+}
+
+func TestRequestPingDebouncesDuplicates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	config := Config{Targets: []TargetSpec{{Name: "local", Kind: "tcp", Address: "127.0.0.1:1"}}}
+	m := NewMonitor(ctx, config)
+	defer m.Close()
+	defer cancel()
+
+	// NewMonitor already has live pingWorker goroutines draining m.pingCh,
+	// so asserting on len(m.pingCh) races those workers. Instead, observe
+	// how many times a request actually gets serviced.
+	replies := make(chan string, 2)
+	reply := func(text string) { replies <- text }
+
+	m.requestPing("local", reply)
+	m.requestPing("local", reply)
+
+	select {
+	case <-replies:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the first /ping to be handled")
+	}
+
+	select {
+	case <-replies:
+		t.Error("expected the duplicate /ping to be debounced, got a second reply")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// testResponder is an in-memory Responder double for exercising
+// handleCommand directly in tests.
+type testResponder struct {
+	text  *string
+	photo *[]byte
+}
+
+func (r testResponder) Reply(text string) { *r.text = text }
+
+func (r testResponder) ReplyPhoto(caption string, png []byte) {
+	*r.text = caption
+	if r.photo != nil {
+		*r.photo = png
+	}
+}
+
+func TestHandleCommandList(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := Config{Targets: []TargetSpec{{Name: "local", Kind: "tcp", Address: "127.0.0.1:1"}}}
+	m := NewMonitor(ctx, config)
+
+	var got string
+	m.handleCommand("/list", nil, "42", testResponder{text: &got})
+
+	if got == "" {
+		t.Fatal("expected a non-empty reply")
+	}
+}
+
+// controllableProber lets a test flip a target between up and down
+// deterministically, without depending on real network state.
+type controllableProber struct {
+	up *atomic.Bool
+}
+
+func (p controllableProber) Probe(ctx context.Context, t TargetSpec) error {
+	if p.up.Load() {
+		return nil
+	}
+	return fmt.Errorf("simulated probe failure")
+}
+
+func TestCheckAppliesFailureAndRecoveryThresholds(t *testing.T) {
+	up := &atomic.Bool{}
+	up.Store(true)
+	proberRegistry["test-hysteresis"] = controllableProber{up: up}
+	defer delete(proberRegistry, "test-hysteresis")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	config := Config{Targets: []TargetSpec{{
+		Name: "svc", Kind: "test-hysteresis",
+		FailureThreshold: 3, RecoveryThreshold: 2,
+	}}}
+	m := NewMonitor(ctx, config)
+	defer m.Close()
+	defer cancel()
+	m.notifiers = []Notifier{&fakeNotifier{}}
+
+	isUp := func() bool {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.targets["svc"].isUp
+	}
+
+	m.check("svc") // first check always declares the observed state
+	if !isUp() {
+		t.Fatal("expected the first check to declare the target up")
+	}
+
+	up.Store(false)
+	m.check("svc")
+	m.check("svc")
+	if !isUp() {
+		t.Fatal("expected the target to stay up below failure_threshold")
+	}
+	m.check("svc")
+	if isUp() {
+		t.Fatal("expected the target to go down once failure_threshold is reached")
+	}
+
+	up.Store(true)
+	m.check("svc")
+	if isUp() {
+		t.Fatal("expected the target to stay down below recovery_threshold")
+	}
+	m.check("svc")
+	if !isUp() {
+		t.Fatal("expected the target to recover once recovery_threshold is reached")
+	}
+}
+
+func TestCheckQueuesAlertsDuringQuietHoursAndFlushesAfter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	config := Config{Targets: []TargetSpec{{
+		Name: "svc", Kind: "tcp", Address: "127.0.0.1:1",
+		QuietHours: "00:00-23:59", // covers "now" for the whole test run
+	}}}
+	m := NewMonitor(ctx, config)
+	defer m.Close()
+	defer cancel()
+
+	fake := &fakeNotifier{}
+	m.notifiers = []Notifier{fake}
+
+	m.check("svc") // first check: the startup alert should queue, not send
+
+	fake.mu.Lock()
+	sent := len(fake.messages)
+	fake.mu.Unlock()
+	if sent != 0 {
+		t.Fatalf("expected the startup alert to be queued during quiet hours, got %d message(s) sent", sent)
+	}
+
+	m.quietMu.Lock()
+	pending := len(m.quietPending["svc"])
+	m.quietMu.Unlock()
+	if pending != 1 {
+		t.Fatalf("expected 1 pending digest message, got %d", pending)
+	}
+
+	// End quiet hours for this target and check again. The probe result
+	// hasn't changed, so this isn't a transition, but the digest should
+	// still flush.
+	m.mu.Lock()
+	m.targets["svc"].spec.QuietHours = ""
+	m.mu.Unlock()
+
+	m.check("svc")
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.messages) != 1 {
+		t.Fatalf("expected exactly 1 digest message after quiet hours ended, got %d", len(fake.messages))
+	}
+	if !strings.Contains(fake.messages[0].Text, "Quiet hours digest") {
+		t.Errorf("expected a digest message, got %q", fake.messages[0].Text)
+	}
+}
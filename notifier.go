@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Severity classifies a Message so each Notifier can render it natively
+// (colored Slack attachment, Telegram emoji, …) without parsing Text.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"     // startup / first check / quiet-hours digest
+	SeverityCritical Severity = "critical" // target went down
+	SeverityOK       Severity = "ok"       // target recovered
+	SeverityWarning  Severity = "warning"  // target is flapping
+)
+
+// Message is a structured alert handed to every configured Notifier.
+type Message struct {
+	Target   string
+	Severity Severity
+	Text     string        // plain-text rendering; always populated
+	Downtime time.Duration // set only when Severity is SeverityOK and this isn't the first check
+}
+
+// Notifier delivers Messages to one destination: a Telegram chat, a Matrix
+// room, a Slack channel, a generic webhook, …
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// ReplyFunc sends a text reply back to wherever a command came from.
+type ReplyFunc func(text string)
+
+// Responder lets a CommandHandler reply to a command with plain text or,
+// for backends that support it, an image (e.g. the /graph sparkline).
+// Backends without image support should fall back to sending the caption
+// as text.
+type Responder interface {
+	Reply(text string)
+	ReplyPhoto(caption string, png []byte)
+}
+
+// CommandHandler processes a bot command. from identifies the chat, room,
+// or channel the command was sent from, in whatever form the originating
+// Notifier uses for addressing.
+type CommandHandler func(cmd string, args []string, from string, r Responder)
+
+// Commander is implemented by Notifiers that support two-way commands (e.g.
+// Telegram's /status). Notifiers without an interactive channel, like the
+// generic webhook, only implement Notifier.
+type Commander interface {
+	Notifier
+	// Listen runs until ctx is canceled, dispatching incoming commands to
+	// handler.
+	Listen(ctx context.Context, handler CommandHandler)
+}
+
+// NotifierConfig configures one notifier backend. Only the fields relevant
+// to Type need to be set; the rest are ignored.
+type NotifierConfig struct {
+	Type    string `json:"type"`    // telegram, matrix, slack, webhook
+	Enabled *bool  `json:"enabled"` // default true when omitted
+
+	// Telegram
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"`
+
+	// Matrix
+	HomeserverURL string `json:"homeserver_url"`
+	UserID        string `json:"user_id"`
+	Password      string `json:"password"`
+	RoomID        string `json:"room_id"`
+
+	// Slack
+	WebhookURL    string `json:"webhook_url"`
+	ListenAddr    string `json:"listen_addr"`    // slash-command receiver bind address, e.g. ":8090"
+	SigningSecret string `json:"signing_secret"` // Slack app's signing secret; required to verify slash-command requests
+
+	// Generic webhook
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+func (nc NotifierConfig) enabled() bool {
+	return nc.Enabled == nil || *nc.Enabled
+}
+
+// buildNotifiers constructs the enabled notifiers from config, each wrapped
+// with retry/backoff.
+func buildNotifiers(config Config) []Notifier {
+	var notifiers []Notifier
+	for _, nc := range config.Notifiers {
+		if !nc.enabled() {
+			continue
+		}
+
+		var n Notifier
+		switch nc.Type {
+		case "telegram":
+			n = NewTelegramNotifier(nc)
+		case "matrix":
+			n = NewMatrixNotifier(nc)
+		case "slack":
+			n = NewSlackNotifier(nc)
+		case "webhook":
+			n = NewWebhookNotifier(nc)
+		default:
+			log.Printf("Unknown notifier type %q, skipping", nc.Type)
+			continue
+		}
+		notifiers = append(notifiers, withRetry(n))
+	}
+	return notifiers
+}
+
+const (
+	notifyMaxAttempts = 3
+	notifyBaseDelay   = 500 * time.Millisecond
+)
+
+// retryingNotifier wraps a Notifier with bounded retry and exponential
+// backoff so one slow or flaky backend doesn't drop an alert on its first
+// hiccup.
+type retryingNotifier struct {
+	Notifier
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+func (r retryingNotifier) Send(ctx context.Context, msg Message) error {
+	var err error
+	delay := r.baseDelay
+	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
+		if err = r.Notifier.Send(ctx, msg); err == nil {
+			return nil
+		}
+		if attempt == r.maxAttempts {
+			break
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+	return fmt.Errorf("%s: giving up after %d attempts: %w", r.Notifier.Name(), r.maxAttempts, err)
+}
+
+// retryingCommander is withRetry's result when the wrapped Notifier also
+// implements Commander: Send gets retry/backoff, Listen is passed through
+// unchanged since long-poll/receiver loops already have their own recovery.
+type retryingCommander struct {
+	inner Commander
+	retry retryingNotifier
+}
+
+func (r retryingCommander) Name() string { return r.inner.Name() }
+
+func (r retryingCommander) Send(ctx context.Context, msg Message) error {
+	return r.retry.Send(ctx, msg)
+}
+
+func (r retryingCommander) Listen(ctx context.Context, handler CommandHandler) {
+	r.inner.Listen(ctx, handler)
+}
+
+func withRetry(n Notifier) Notifier {
+	retry := retryingNotifier{Notifier: n, maxAttempts: notifyMaxAttempts, baseDelay: notifyBaseDelay}
+	if c, ok := n.(Commander); ok {
+		return retryingCommander{inner: c, retry: retry}
+	}
+	return retry
+}
+
+func asCommander(n Notifier) (Commander, bool) {
+	c, ok := n.(Commander)
+	return c, ok
+}
+
+func severityEmoji(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "🔴"
+	case SeverityOK:
+		return "🟢"
+	case SeverityWarning:
+		return "⚠️"
+	default:
+		return "ℹ️"
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// maxHTTPBodyCheckBytes bounds how much of an HTTP response body
+// httpProber reads when validating TargetSpec.ExpectedBody, so a
+// misbehaving server can't force it to buffer an unbounded response.
+const maxHTTPBodyCheckBytes = 1 << 20 // 1 MiB
+
+// Prober checks the reachability of a single target. A nil error means the
+// target is considered up; any non-nil error (timeout, connection refused,
+// unexpected status, …) means it's down.
+type Prober interface {
+	Probe(ctx context.Context, t TargetSpec) error
+}
+
+// proberRegistry maps a TargetSpec.Kind to the Prober that handles it.
+// Adding a new probe type (e.g. "udp") means adding an entry here, not
+// touching check().
+var proberRegistry = map[string]Prober{
+	"icmp": icmpProber{},
+	"tcp":  tcpProber{},
+	"http": httpProber{},
+	"dns":  dnsProber{},
+}
+
+type icmpProber struct{}
+
+func (icmpProber) Probe(ctx context.Context, t TargetSpec) error {
+	cmd := exec.CommandContext(ctx, "ping", "-c", "3", "-W", "2", t.Address)
+	return cmd.Run()
+}
+
+type tcpProber struct{}
+
+func (tcpProber) Probe(ctx context.Context, t TargetSpec) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", t.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+type dnsProber struct{}
+
+func (dnsProber) Probe(ctx context.Context, t TargetSpec) error {
+	var r net.Resolver
+	addrs, err := r.LookupHost(ctx, t.Address)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses returned for %s", t.Address)
+	}
+	return nil
+}
+
+type httpProber struct{}
+
+func (httpProber) Probe(ctx context.Context, t TargetSpec) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.Address, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if t.ExpectedStatus != 0 {
+		if resp.StatusCode != t.ExpectedStatus {
+			return fmt.Errorf("expected status %d, got %d", t.ExpectedStatus, resp.StatusCode)
+		}
+	} else if resp.StatusCode >= 400 {
+		// No expected status configured: any non-error response means the
+		// server is reachable.
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if t.ExpectedBody != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodyCheckBytes))
+		if err != nil {
+			return fmt.Errorf("reading response body: %w", err)
+		}
+		if !strings.Contains(string(body), t.ExpectedBody) {
+			return fmt.Errorf("response body does not contain %q", t.ExpectedBody)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTCPProber(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	defer ln.Close()
+
+	if err := (tcpProber{}).Probe(context.Background(), TargetSpec{Kind: "tcp", Address: ln.Addr().String()}); err != nil {
+		t.Errorf("Probe against a listening port: %v", err)
+	}
+
+	closedAddr := ln.Addr().String()
+	ln.Close()
+
+	if err := (tcpProber{}).Probe(context.Background(), TargetSpec{Kind: "tcp", Address: closedAddr}); err == nil {
+		t.Error("Probe against a closed port: expected an error, got nil")
+	}
+}
+
+func TestDNSProber(t *testing.T) {
+	if err := (dnsProber{}).Probe(context.Background(), TargetSpec{Kind: "dns", Address: "localhost"}); err != nil {
+		t.Errorf("Probe for a resolvable host: %v", err)
+	}
+
+	if err := (dnsProber{}).Probe(context.Background(), TargetSpec{Kind: "dns", Address: "this-host-does-not-exist.invalid"}); err == nil {
+		t.Error("Probe for an unresolvable host: expected an error, got nil")
+	}
+}
+
+func TestHTTPProberDefaultStatusCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	if err := (httpProber{}).Probe(context.Background(), TargetSpec{Kind: "http", Address: server.URL}); err != nil {
+		t.Errorf("Probe with no ExpectedStatus against a 200: %v", err)
+	}
+
+	if err := (httpProber{}).Probe(context.Background(), TargetSpec{Kind: "http", Address: server.URL + "/error"}); err == nil {
+		t.Error("Probe with no ExpectedStatus against a 500: expected an error, got nil")
+	}
+}
+
+func TestHTTPProberExpectedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("status: ok, version 1.2.3"))
+	}))
+	defer server.Close()
+
+	spec := TargetSpec{Kind: "http", Address: server.URL, ExpectedBody: "status: ok"}
+	if err := (httpProber{}).Probe(context.Background(), spec); err != nil {
+		t.Errorf("Probe with matching body: %v", err)
+	}
+
+	spec.ExpectedBody = "status: degraded"
+	if err := (httpProber{}).Probe(context.Background(), spec); err == nil {
+		t.Error("Probe with non-matching body: expected an error, got nil")
+	}
+}
+
+func TestHTTPProberExpectedStatusAndBodyCombine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("queued"))
+	}))
+	defer server.Close()
+
+	spec := TargetSpec{Kind: "http", Address: server.URL, ExpectedStatus: http.StatusAccepted, ExpectedBody: "queued"}
+	if err := (httpProber{}).Probe(context.Background(), spec); err != nil {
+		t.Errorf("Probe with matching status and body: %v", err)
+	}
+
+	spec.ExpectedStatus = http.StatusOK
+	if err := (httpProber{}).Probe(context.Background(), spec); err == nil {
+		t.Error("Probe with mismatched status: expected an error, got nil")
+	}
+}
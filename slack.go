@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSlackSignatureAge bounds how old an X-Slack-Request-Timestamp may be,
+// so a captured request/signature pair can't be replayed indefinitely.
+const maxSlackSignatureAge = 5 * time.Minute
+
+// SlackNotifier posts alerts to a Slack incoming webhook and, when
+// ListenAddr is configured, runs an HTTP server that receives Slack
+// slash-command callbacks.
+type SlackNotifier struct {
+	webhookURL    string
+	listenAddr    string
+	signingSecret string
+	client        *http.Client
+}
+
+func NewSlackNotifier(cfg NotifierConfig) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL:    cfg.WebhookURL,
+		listenAddr:    cfg.ListenAddr,
+		signingSecret: cfg.SigningSecret,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *SlackNotifier) Name() string { return "slack" }
+
+func (s *SlackNotifier) Send(ctx context.Context, msg Message) error {
+	color := "#36a64f"
+	switch msg.Severity {
+	case SeverityCritical:
+		color = "#d00000"
+	case SeverityWarning:
+		color = "#daa038"
+	}
+
+	fields := []map[string]any{
+		{"title": "Target", "value": msg.Target, "short": true},
+		{"title": "Severity", "value": string(msg.Severity), "short": true},
+	}
+	if msg.Downtime > 0 {
+		fields = append(fields, map[string]any{"title": "Downtime", "value": msg.Downtime.String(), "short": true})
+	}
+
+	payload := map[string]any{
+		"attachments": []map[string]any{{
+			"color":  color,
+			"text":   msg.Text,
+			"fields": fields,
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackResponder implements Responder for a single slash-command request.
+// Slack has no way to attach an image to a slash-command's synchronous
+// response, so ReplyPhoto falls back to sending the caption as text.
+type slackResponder struct {
+	reply *string
+}
+
+func (r slackResponder) Reply(text string) { *r.reply = text }
+
+func (r slackResponder) ReplyPhoto(caption string, _ []byte) {
+	*r.reply = caption + " (image replies aren't supported over Slack slash commands)"
+}
+
+// Listen runs a small HTTP server that answers Slack slash commands until
+// ctx is canceled. Commands are answered synchronously in the request, so
+// the async follow-up from a /ping (sent once the check completes) only
+// reaches notifiers that support out-of-band replies, like Telegram.
+//
+// Every request's signature is verified against signingSecret before it
+// reaches handler; Listen refuses to start at all without one configured,
+// since an unverified receiver would let anyone who can reach listenAddr
+// trigger /ping and read status.
+func (s *SlackNotifier) Listen(ctx context.Context, handler CommandHandler) {
+	if s.listenAddr == "" {
+		log.Printf("slack: listen_addr not configured, slash commands disabled")
+		<-ctx.Done()
+		return
+	}
+	if s.signingSecret == "" {
+		log.Printf("slack: signing_secret not configured, refusing to start an unauthenticated slash-command receiver")
+		<-ctx.Done()
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slack/command", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if !s.verifySignature(r, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		cmd := r.FormValue("command")
+		channel := r.FormValue("channel_id")
+		var args []string
+		if text := strings.TrimSpace(r.FormValue("text")); text != "" {
+			args = strings.Fields(text)
+		}
+
+		var reply string
+		handler(cmd, args, channel, slackResponder{reply: &reply})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"text": reply})
+	})
+
+	server := &http.Server{Addr: s.listenAddr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Printf("slack: command server error: %v", err)
+	}
+}
+
+// verifySignature checks r's X-Slack-Signature against body, per Slack's
+// request-signing scheme: https://api.slack.com/authentication/verifying-requests-from-slack
+func (s *SlackNotifier) verifySignature(r *http.Request, body []byte) bool {
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > maxSlackSignatureAge || age < -maxSlackSignatureAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Slack-Signature")))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func signSlackRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSlackVerifySignature(t *testing.T) {
+	s := &SlackNotifier{signingSecret: "sekrit"}
+	body := []byte("command=%2Flist&channel_id=C1")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	good := httptest.NewRequest(http.MethodPost, "/slack/command", nil)
+	good.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	good.Header.Set("X-Slack-Signature", signSlackRequest("sekrit", timestamp, body))
+	if !s.verifySignature(good, body) {
+		t.Error("expected a correctly signed request to verify")
+	}
+
+	wrongSecret := httptest.NewRequest(http.MethodPost, "/slack/command", nil)
+	wrongSecret.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	wrongSecret.Header.Set("X-Slack-Signature", signSlackRequest("other-secret", timestamp, body))
+	if s.verifySignature(wrongSecret, body) {
+		t.Error("expected a request signed with the wrong secret to fail verification")
+	}
+
+	tamperedBody := httptest.NewRequest(http.MethodPost, "/slack/command", nil)
+	tamperedBody.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	tamperedBody.Header.Set("X-Slack-Signature", signSlackRequest("sekrit", timestamp, body))
+	if s.verifySignature(tamperedBody, []byte("command=%2Fping&channel_id=C1")) {
+		t.Error("expected a tampered body to fail verification")
+	}
+
+	stale := httptest.NewRequest(http.MethodPost, "/slack/command", nil)
+	staleTimestamp := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	stale.Header.Set("X-Slack-Request-Timestamp", staleTimestamp)
+	stale.Header.Set("X-Slack-Signature", signSlackRequest("sekrit", staleTimestamp, body))
+	if s.verifySignature(stale, body) {
+		t.Error("expected a stale timestamp to fail verification")
+	}
+}
+
+func TestSlackListenRefusesWithoutSigningSecret(t *testing.T) {
+	s := NewSlackNotifier(NotifierConfig{ListenAddr: ":0"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Listen(ctx, nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Listen to return once its context is canceled")
+	}
+}
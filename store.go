@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// CheckResult is one recorded probe outcome for a target.
+type CheckResult struct {
+	Target    string        `json:"target"`
+	Timestamp time.Time     `json:"timestamp"`
+	Up        bool          `json:"up"`
+	Latency   time.Duration `json:"latency"`
+}
+
+// StateTransition records a target flipping up/down.
+type StateTransition struct {
+	Target    string    `json:"target"`
+	Timestamp time.Time `json:"timestamp"`
+	Up        bool      `json:"up"`
+}
+
+type uptimeWindowSpec struct {
+	Label    string
+	Duration time.Duration
+}
+
+// uptimeWindows are the rolling windows surfaced in /metrics and /status.
+var uptimeWindows = []uptimeWindowSpec{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+var (
+	checksBucket      = []byte("checks")
+	transitionsBucket = []byte("transitions")
+)
+
+const defaultCompactInterval = time.Hour
+
+// Store persists check history and state transitions in a BoltDB file so
+// uptime metrics and history queries survive a restart.
+type Store struct {
+	db            *bbolt.DB
+	retentionDays int
+}
+
+// OpenStore opens (creating if necessary) the BoltDB file at path. A
+// retentionDays of 0 disables Compact.
+func OpenStore(path string, retentionDays int) (*Store, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(checksBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(transitionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init store: %w", err)
+	}
+
+	return &Store{db: db, retentionDays: retentionDays}, nil
+}
+
+func (s *Store) Close() error { return s.db.Close() }
+
+// recordKey orders records by target then timestamp so a range scan over a
+// single target's history is contiguous.
+func recordKey(target string, ts time.Time) []byte {
+	key := make([]byte, len(target)+1+8)
+	copy(key, target)
+	key[len(target)] = 0
+	binary.BigEndian.PutUint64(key[len(target)+1:], uint64(ts.UnixNano()))
+	return key
+}
+
+func (s *Store) RecordCheck(r CheckResult) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(checksBucket).Put(recordKey(r.Target, r.Timestamp), data)
+	})
+}
+
+func (s *Store) RecordTransition(t StateTransition) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(transitionsBucket).Put(recordKey(t.Target, t.Timestamp), data)
+	})
+}
+
+// History returns every check recorded for target since the given time,
+// oldest first.
+func (s *Store) History(target string, since time.Time) ([]CheckResult, error) {
+	var results []CheckResult
+	prefix := append([]byte(target), 0)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(checksBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && hasPrefix(k, prefix); k, v = c.Next() {
+			var r CheckResult
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if r.Timestamp.Before(since) {
+				continue
+			}
+			results = append(results, r)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// TransitionCount returns how many up/down transitions have been recorded
+// for target.
+func (s *Store) TransitionCount(target string) (int, error) {
+	count := 0
+	prefix := append([]byte(target), 0)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(transitionsBucket).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && hasPrefix(k, prefix); k, _ = c.Next() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func hasPrefix(k, prefix []byte) bool {
+	if len(k) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if k[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// UptimeRatio returns the fraction of checks that were "up" for target
+// within the trailing window, and the mean latency across those checks.
+func (s *Store) UptimeRatio(target string, window time.Duration) (ratio float64, meanLatency time.Duration, err error) {
+	results, err := s.History(target, time.Now().Add(-window))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(results) == 0 {
+		return 0, 0, nil
+	}
+
+	var up int
+	var totalLatency time.Duration
+	for _, r := range results {
+		if r.Up {
+			up++
+		}
+		totalLatency += r.Latency
+	}
+	return float64(up) / float64(len(results)), totalLatency / time.Duration(len(results)), nil
+}
+
+// Compact deletes check and transition records older than retentionDays. A
+// retentionDays of 0 makes it a no-op.
+func (s *Store) Compact() error {
+	if s.retentionDays <= 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -s.retentionDays)
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{checksBucket, transitionsBucket} {
+			b := tx.Bucket(bucket)
+			c := b.Cursor()
+
+			var stale [][]byte
+			for k, v := c.First(); k != nil; k, v = c.Next() {
+				ts, ok := recordTimestamp(v)
+				if ok && ts.Before(cutoff) {
+					stale = append(stale, append([]byte(nil), k...))
+				}
+			}
+			for _, k := range stale {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func recordTimestamp(data []byte) (time.Time, bool) {
+	var r struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return time.Time{}, false
+	}
+	return r.Timestamp, true
+}
+
+// RunCompactor periodically compacts the store until ctx is canceled.
+func (s *Store) RunCompactor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Compact(); err != nil {
+				log.Printf("store: compact failed: %v", err)
+			}
+		}
+	}
+}
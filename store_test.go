@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreHistoryAndUptime(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.db"), 30)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	checks := []CheckResult{
+		{Target: "local", Timestamp: now.Add(-3 * time.Hour), Up: true, Latency: 10 * time.Millisecond},
+		{Target: "local", Timestamp: now.Add(-2 * time.Hour), Up: false, Latency: 0},
+		{Target: "local", Timestamp: now.Add(-1 * time.Hour), Up: true, Latency: 20 * time.Millisecond},
+	}
+	for _, c := range checks {
+		if err := store.RecordCheck(c); err != nil {
+			t.Fatalf("RecordCheck: %v", err)
+		}
+	}
+
+	history, err := store.History("local", now.Add(-4*time.Hour))
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 history entries, got %d", len(history))
+	}
+
+	ratio, mean, err := store.UptimeRatio("local", 4*time.Hour)
+	if err != nil {
+		t.Fatalf("UptimeRatio: %v", err)
+	}
+	if want := 2.0 / 3.0; ratio < want-0.001 || ratio > want+0.001 {
+		t.Errorf("ratio = %f, want ~%f", ratio, want)
+	}
+	if want := 10 * time.Millisecond; mean != want {
+		t.Errorf("mean latency = %v, want %v", mean, want)
+	}
+}
+
+func TestStoreCompactDeletesStaleRecords(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "history.db"), 1)
+	if err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	if err := store.RecordCheck(CheckResult{Target: "local", Timestamp: now.AddDate(0, 0, -2), Up: true}); err != nil {
+		t.Fatalf("RecordCheck (stale): %v", err)
+	}
+	if err := store.RecordCheck(CheckResult{Target: "local", Timestamp: now, Up: true}); err != nil {
+		t.Fatalf("RecordCheck (fresh): %v", err)
+	}
+
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	history, err := store.History("local", now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected 1 surviving record after compact, got %d", len(history))
+	}
+}
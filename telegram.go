@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultTelegramAPIBase = "https://api.telegram.org"
+
+// TelegramNotifier sends alerts to a Telegram chat and, via Listen, answers
+// bot commands (/status, /list, /ping, …) sent to that chat.
+type TelegramNotifier struct {
+	token   string
+	chatID  string
+	apiBase string
+	client  *http.Client
+}
+
+func NewTelegramNotifier(cfg NotifierConfig) *TelegramNotifier {
+	return &TelegramNotifier{
+		token:   cfg.BotToken,
+		chatID:  cfg.ChatID,
+		apiBase: defaultTelegramAPIBase,
+		client: &http.Client{
+			Timeout: 35 * time.Second, // covers getUpdates' 30s long-poll plus margin
+			Transport: &http.Transport{
+				MaxIdleConns:        20,
+				MaxIdleConnsPerHost: 20,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+}
+
+func (t *TelegramNotifier) Name() string { return "telegram" }
+
+func (t *TelegramNotifier) Send(ctx context.Context, msg Message) error {
+	text := fmt.Sprintf("%s *%s*\n%s", severityEmoji(msg.Severity), escapeMarkdownV2(msg.Target), escapeMarkdownV2(msg.Text))
+	if msg.Downtime > 0 {
+		text += fmt.Sprintf("\n_downtime: %s_", escapeMarkdownV2(msg.Downtime.String()))
+	}
+	return t.postMessage(ctx, t.chatID, text, true)
+}
+
+func (t *TelegramNotifier) postMessage(ctx context.Context, chatID, text string, markdown bool) error {
+	apiURL := fmt.Sprintf("%s/bot%s/sendMessage", t.apiBase, t.token)
+	form := url.Values{
+		"chat_id": {chatID},
+		"text":    {text},
+	}
+	if markdown {
+		form.Set("parse_mode", "MarkdownV2")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("telegram API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// postPhoto uploads a PNG via Telegram's sendPhoto endpoint, used by the
+// /graph command.
+func (t *TelegramNotifier) postPhoto(ctx context.Context, chatID, caption string, photo []byte) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	if err := mw.WriteField("chat_id", chatID); err != nil {
+		return err
+	}
+	if caption != "" {
+		if err := mw.WriteField("caption", caption); err != nil {
+			return err
+		}
+	}
+	part, err := mw.CreateFormFile("photo", "graph.png")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(photo); err != nil {
+		return err
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	apiURL := fmt.Sprintf("%s/bot%s/sendPhoto", t.apiBase, t.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("telegram API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// escapeMarkdownV2 escapes the characters Telegram's MarkdownV2 parse mode
+// treats as special, most notably '.' which shows up in almost every IP
+// address and hostname this bot sends.
+func escapeMarkdownV2(s string) string {
+	const specials = "_*[]()~`>#+-=|{}.!"
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(specials, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// telegramResponder implements Responder for a single incoming message,
+// replying to the chat it came from.
+type telegramResponder struct {
+	t      *TelegramNotifier
+	ctx    context.Context
+	chatID string
+}
+
+func (r telegramResponder) Reply(text string) {
+	if err := r.t.postMessage(r.ctx, r.chatID, escapeMarkdownV2(text), false); err != nil {
+		log.Printf("telegram: failed to reply: %v", err)
+	}
+}
+
+func (r telegramResponder) ReplyPhoto(caption string, photo []byte) {
+	if err := r.t.postPhoto(r.ctx, r.chatID, caption, photo); err != nil {
+		log.Printf("telegram: failed to send photo: %v", err)
+	}
+}
+
+type telegramUpdate struct {
+	UpdateID int `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+func (t *TelegramNotifier) getUpdates(ctx context.Context, offset int) ([]telegramUpdate, error) {
+	apiURL := fmt.Sprintf("%s/bot%s/getUpdates?offset=%d&timeout=30", t.apiBase, t.token, offset)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK     bool             `json:"ok"`
+		Result []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Result, nil
+}
+
+// Listen long-polls Telegram for updates until ctx is canceled, dispatching
+// each message's command to handler.
+func (t *TelegramNotifier) Listen(ctx context.Context, handler CommandHandler) {
+	offset := 0
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		updates, err := t.getUpdates(ctx, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("telegram: error getting updates: %v", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil {
+				continue
+			}
+
+			text := strings.TrimSpace(update.Message.Text)
+			chatID := fmt.Sprintf("%d", update.Message.Chat.ID)
+			fields := strings.Fields(text)
+			if len(fields) == 0 {
+				continue
+			}
+
+			log.Printf("telegram: received message from chat %s: %s", chatID, text)
+
+			handler(fields[0], fields[1:], chatID, telegramResponder{t: t, ctx: ctx, chatID: chatID})
+		}
+
+		select {
+		case <-time.After(1 * time.Second):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTelegramNotifierSend(t *testing.T) {
+	var gotText string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotText = r.FormValue("text")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer server.Close()
+
+	tn := NewTelegramNotifier(NotifierConfig{BotToken: "tok", ChatID: "1"})
+	tn.apiBase = server.URL
+
+	err := tn.Send(context.Background(), Message{Target: "local", Severity: SeverityCritical, Text: "local is DOWN!"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(gotText, "local") {
+		t.Errorf("expected rendered text to include target name, got %q", gotText)
+	}
+}
+
+func TestTelegramNotifierListenDispatchesCommands(t *testing.T) {
+	sentUpdate := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "getUpdates") && !sentUpdate {
+			sentUpdate = true
+			json.NewEncoder(w).Encode(map[string]any{
+				"ok": true,
+				"result": []map[string]any{{
+					"update_id": 1,
+					"message": map[string]any{
+						"text": "/list",
+						"chat": map[string]any{"id": 42},
+					},
+				}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": []any{}})
+	}))
+	defer server.Close()
+
+	tn := NewTelegramNotifier(NotifierConfig{BotToken: "tok", ChatID: "1"})
+	tn.apiBase = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var gotCmd, gotFrom string
+	handler := func(cmd string, args []string, from string, r Responder) {
+		gotCmd, gotFrom = cmd, from
+		r.Reply("ok")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		tn.Listen(ctx, handler)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-done
+
+	if gotCmd != "/list" || gotFrom != "42" {
+		t.Errorf("handler got cmd=%q from=%q, want /list from 42", gotCmd, gotFrom)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier POSTs a JSON-encoded Message to an arbitrary HTTP
+// endpoint. It has no two-way command support, so it only implements
+// Notifier, not Commander.
+type WebhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func NewWebhookNotifier(cfg NotifierConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *WebhookNotifier) Name() string { return "webhook" }
+
+type webhookPayload struct {
+	Target     string `json:"target"`
+	Severity   string `json:"severity"`
+	Text       string `json:"text"`
+	DowntimeMS int64  `json:"downtime_ms,omitempty"`
+}
+
+func (w *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	payload := webhookPayload{
+		Target:   msg.Target,
+		Severity: string(msg.Severity),
+		Text:     msg.Text,
+	}
+	if msg.Downtime > 0 {
+		payload.DowntimeMS = msg.Downtime.Milliseconds()
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
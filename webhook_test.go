@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifierSend(t *testing.T) {
+	var gotPayload webhookPayload
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+		gotHeader = r.Header.Get("X-Api-Key")
+		json.NewDecoder(r.Body).Decode(&gotPayload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wn := NewWebhookNotifier(NotifierConfig{URL: server.URL, Headers: map[string]string{"X-Api-Key": "secret"}})
+
+	err := wn.Send(context.Background(), Message{
+		Target:   "local",
+		Severity: SeverityCritical,
+		Text:     "local is DOWN!",
+		Downtime: 90 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if gotHeader != "secret" {
+		t.Errorf("X-Api-Key = %q, want secret", gotHeader)
+	}
+	if gotPayload.Target != "local" || gotPayload.Severity != string(SeverityCritical) || gotPayload.Text != "local is DOWN!" {
+		t.Errorf("unexpected payload: %+v", gotPayload)
+	}
+	if gotPayload.DowntimeMS != 90000 {
+		t.Errorf("DowntimeMS = %d, want 90000", gotPayload.DowntimeMS)
+	}
+}
+
+func TestWebhookNotifierSendErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	wn := NewWebhookNotifier(NotifierConfig{URL: server.URL})
+
+	if err := wn.Send(context.Background(), Message{Target: "local", Text: "hi"}); err == nil {
+		t.Error("expected a 500 response to surface as an error")
+	}
+}